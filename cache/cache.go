@@ -0,0 +1,86 @@
+// Package cache provides a disk-backed, TTL-aware store for metadata
+// provider lookups, so repeated runs over the same catalog don't have to
+// hit the network for files that were already resolved.
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/malashin/yarpt/provider"
+)
+
+// DefaultNegativeTTL is how long a failed or incomplete lookup is cached
+// before it is retried, so transient API errors recover quickly.
+const DefaultNegativeTTL = 5 * time.Minute
+
+var bucketName = []byte("movies")
+
+// Entry is a single cached lookup result.
+type Entry struct {
+	Movie    provider.Movie `json:"movie"`
+	Cached   time.Time      `json:"cached"`
+	Negative bool           `json:"negative"` // true if the lookup failed or returned incomplete data
+}
+
+// IsStale reports whether e was cached longer than ttl ago.
+func (e Entry) IsStale(ttl time.Duration) bool {
+	return time.Since(e.Cached) > ttl
+}
+
+// Cache is a disk-backed store of provider lookup results keyed by ID.
+type Cache struct {
+	db *bolt.DB
+}
+
+// Open opens (creating if necessary) a cache database at path.
+func Open(path string) (*Cache, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Cache{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Get returns the cached entry for id, if any.
+func (c *Cache) Get(id string) (entry Entry, found bool, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &entry)
+	})
+	return entry, found, err
+}
+
+// Put stores m under id, stamped with the current time. negative marks a
+// failed or incomplete lookup so callers can apply a shorter TTL to it.
+func (c *Cache) Put(id string, m provider.Movie, negative bool) error {
+	data, err := json.Marshal(Entry{Movie: m, Cached: time.Now(), Negative: negative})
+	if err != nil {
+		return err
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(id), data)
+	})
+}
@@ -0,0 +1,32 @@
+package hash
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// TestEd2kHasherSingleChunkExact covers the edge case where a file's size
+// is an exact multiple of ed2kChunkSize with exactly one chunk: both the
+// "blue" and "red" variants must still go through the concat-and-rehash
+// step, not just return the raw per-chunk MD4.
+func TestEd2kHasherSingleChunkExact(t *testing.T) {
+	buf := make([]byte, ed2kChunkSize)
+
+	h := newEd2kHasher()
+	if _, err := h.Write(buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	blue, red := h.Sum()
+
+	wantBlue := "7afd7280385f4734932830cac10a92cf"
+	wantRed := "fc21d9af828f92a8df64beac3357425d"
+	if got := hex.EncodeToString(blue); got != wantBlue {
+		t.Errorf("blue = %s, want %s", got, wantBlue)
+	}
+	if got := hex.EncodeToString(red); got != wantRed {
+		t.Errorf("red = %s, want %s", got, wantRed)
+	}
+	if hex.EncodeToString(blue) == hex.EncodeToString(md4Sum(buf)) {
+		t.Error("blue must not be the raw per-chunk MD4 for an exact-multiple file")
+	}
+}
@@ -0,0 +1,86 @@
+// Package hash computes content hashes (CRC32, eD2k, SHA1) for a file in
+// a single streamed read, so callers can dedup files by content and look
+// up cached metadata by (hash, size) instead of by filename.
+package hash
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+)
+
+// Result holds the requested content hashes for one file, hex-encoded.
+type Result struct {
+	CRC32 string
+	SHA1  string
+	Ed2k  string // "blue" variant
+	// Ed2kRed is the eD2k "red" variant, only set (and different from
+	// Ed2k) for files whose size is an exact multiple of ed2kChunkSize.
+	Ed2kRed string
+}
+
+// ParseAlgorithms splits a comma-separated --hash flag value such as
+// "crc32,ed2k,sha1" into the set of algorithm names to compute.
+func ParseAlgorithms(flagValue string) map[string]bool {
+	algos := make(map[string]bool)
+	for _, a := range strings.Split(flagValue, ",") {
+		a = strings.TrimSpace(strings.ToLower(a))
+		if a != "" {
+			algos[a] = true
+		}
+	}
+	return algos
+}
+
+// Compute streams path once and computes every hash named in algos.
+// It returns a zero Result if algos is empty.
+func Compute(path string, algos map[string]bool) (Result, error) {
+	var r Result
+	if len(algos) == 0 {
+		return r, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return r, err
+	}
+	defer f.Close()
+
+	var writers []io.Writer
+
+	crc32h := crc32.NewIEEE()
+	if algos["crc32"] {
+		writers = append(writers, crc32h)
+	}
+	sha1h := sha1.New()
+	if algos["sha1"] {
+		writers = append(writers, sha1h)
+	}
+	ed2kh := newEd2kHasher()
+	if algos["ed2k"] {
+		writers = append(writers, ed2kh)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), f); err != nil {
+		return r, err
+	}
+
+	if algos["crc32"] {
+		r.CRC32 = hex.EncodeToString(crc32h.Sum(nil))
+	}
+	if algos["sha1"] {
+		r.SHA1 = hex.EncodeToString(sha1h.Sum(nil))
+	}
+	if algos["ed2k"] {
+		blue, red := ed2kh.Sum()
+		r.Ed2k = hex.EncodeToString(blue)
+		if !bytes.Equal(red, blue) {
+			r.Ed2kRed = hex.EncodeToString(red)
+		}
+	}
+	return r, nil
+}
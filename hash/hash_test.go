@@ -0,0 +1,29 @@
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestComputeEd2kRedOmittedWhenIdentical covers the common case: for a
+// file that isn't an exact multiple of ed2kChunkSize, the red variant is
+// identical to blue and must not be surfaced as a redundant column.
+func TestComputeEd2kRedOmittedWhenIdentical(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	r, err := Compute(path, map[string]bool{"ed2k": true})
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+	if r.Ed2k == "" {
+		t.Fatal("Ed2k is empty")
+	}
+	if r.Ed2kRed != "" {
+		t.Errorf("Ed2kRed = %q, want empty since it's identical to Ed2k", r.Ed2kRed)
+	}
+}
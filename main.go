@@ -2,47 +2,61 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"math"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/malashin/ffinfo"
+	"github.com/malashin/yarpt/cache"
+	"github.com/malashin/yarpt/hash"
+	"github.com/malashin/yarpt/nameparser"
+	"github.com/malashin/yarpt/provider"
+	"github.com/malashin/yarpt/report"
+	"github.com/malashin/yarpt/techinfo"
+	"github.com/malashin/yarpt/thumbnail"
 
 	ansi "github.com/k0kubun/go-ansi"
-	"golang.org/x/net/html/charset"
 )
 
-// apiURL and clientID for KinoPoisk api
-// must be set here before compilation
-var apiURL string
-var clientID string
 var fileListName = "fileList.txt"
-var outputFileName = "report.txt"
+
+// Command line flags controlling the on-disk metadata lookup cache and
+// the report output.
+var (
+	cacheDirFlag = flag.String("cache-dir", ".yarpt-cache", "directory for the persistent metadata lookup cache")
+	cacheTTLFlag = flag.Duration("cache-ttl", 336*time.Hour, "how long successful metadata lookups stay fresh")
+	noCacheFlag  = flag.Bool("no-cache", false, "disable the on-disk metadata lookup cache")
+	jobsFlag     = flag.Int("jobs", 4, "number of files to process concurrently")
+	formatFlag   = flag.String("format", "tsv", "report output format: tsv, csv, ndjson, json, html")
+	outputFlag   = flag.String("output", "", "report output file path (default report.<extension> for the chosen format)")
+	hashFlag     = flag.String("hash", "", "comma-separated content hashes to compute per file: crc32, ed2k, sha1")
+
+	thumbnailsFlag        = flag.Bool("thumbnails", false, "generate a contact-sheet image per file, referenced from the report")
+	thumbnailsGridFlag    = flag.String("thumbnails-grid", "4x4", "contact sheet grid, as COLSxROWS")
+	thumbnailsWidthFlag   = flag.Int("thumbnails-width", 320, "width of each contact sheet tile, in pixels")
+	thumbnailsQualityFlag = flag.Int("thumbnails-quality", 85, "contact sheet JPEG quality, 1 (worst) to 100 (best)")
+
+	filenameRulesFlag = flag.String("filename-rules", "", "path to a YAML file of filename parsing rules (default: built-in rules)")
+)
 
 // List of predetermined durations for the report.
 var durationTypes = []int{90, 60, 30, 10, 5}
 
 var regexpMap = map[string]*regexp.Regexp{
-	"seCoid":           regexp.MustCompile(`.*?(?:s(\d{2})e(\d{2,4}))?(?:\_)?coid(\d+).*_r(\d+)x(\d+)p.*`),
 	"durationHHMMSSMS": regexp.MustCompile(`.*Duration: (\d{2}\:\d{2}\:\d{2}\.\d{2}).*`),
 }
 
-type movie struct {
-	Title         string `json:"title"`
-	OriginalTitle string `json:"originalTitle"`
-	Type          string `json:"type"`
-}
-
 // consolePrint prints str to console while cursor is hidden.
 func consolePrint(str ...interface{}) {
 	ansi.Print("\x1b[?25l") // Hide the cursor.
@@ -66,17 +80,6 @@ func readLines(path string) ([]string, error) {
 	return lines, scanner.Err()
 }
 
-func stripEscapesFromString(str string) string {
-	return regexp.MustCompile(`(\x1b\[\d+m|\x1b\[\d+;\d+m)`).ReplaceAllString(str, "")
-}
-
-func writeStringToFile(file *os.File, input string, perm os.FileMode) {
-	if _, err := file.WriteString(stripEscapesFromString(input)); err != nil {
-		consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
-		os.Exit(1)
-	}
-}
-
 // truncPad truncs or pads string to needed length.
 // If side is 'r' the sring is padded and aligned to the right side.
 // Otherwise it is aligned to the left side.
@@ -91,51 +94,248 @@ func truncPad(s string, n int, side byte) string {
 	return s + strings.Repeat(" ", n-len)
 }
 
-// getMetaFromKP takes KinoPoisk ID and returns movies name in strings.
-func getMetaFromKP(id string) (string, string, error) {
-	if clientID == "" {
-		return "", "", errors.New("clientID for KinoPoisk api is not provided")
+// lookupMovie resolves id through p, preferring a fresh cache entry keyed
+// by cacheKey (or, failing that, fileHashKey — derived from the file's
+// content hash so a renamed file still reuses its old lookup) over a
+// network round-trip. Negative (failed/incomplete) lookups are cached
+// for cache.DefaultNegativeTTL instead of ttl so a retry on the next run
+// recovers quickly.
+func lookupMovie(c *cache.Cache, p provider.MetadataProvider, cacheKey, fileHashKey, id string, ttl time.Duration) (string, string, error) {
+	if c != nil {
+		for _, key := range []string{cacheKey, fileHashKey} {
+			if key == "" {
+				continue
+			}
+			entry, found, err := c.Get(key)
+			if err != nil || !found {
+				continue
+			}
+			entryTTL := ttl
+			if entry.Negative {
+				entryTTL = cache.DefaultNegativeTTL
+			}
+			if !entry.IsStale(entryTTL) {
+				return entry.Movie.Title, entry.Movie.Type, nil
+			}
+		}
 	}
 
-	req, err := http.NewRequest("GET", apiURL+id, nil)
-	if err != nil {
-		return "", "", err
+	m, err := p.LookupByID(context.Background(), id)
+	var movieName, movieType string
+	if m != nil {
+		movieName, movieType = m.Title, m.Type
 	}
-	req.Header.Set("Clientid", clientID)
+	if c != nil {
+		negative := err != nil || movieName == "" || movieType == ""
+		c.Put(cacheKey, provider.Movie{Title: movieName, Type: movieType}, negative)
+		if fileHashKey != "" {
+			c.Put(fileHashKey, provider.Movie{Title: movieName, Type: movieType}, negative)
+		}
+	}
+	return movieName, movieType, err
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// fileJob is one unit of work handed to a worker: the index of f within
+// the original fileList, kept so results can be reassembled in order.
+type fileJob struct {
+	index int
+	path  string
+}
+
+// fileResult is what a worker hands back to the collector. err is a
+// fatal processing error for this file; record and progress are only
+// valid when err is nil. skip marks a file that couldn't be parsed or
+// identified — it's logged as a warning and left out of the report, but
+// doesn't abort the run the way err does.
+type fileResult struct {
+	index    int
+	record   report.Record
+	progress string
+	skip     bool
+	err      error
+}
+
+// thumbnailConfig controls whether processFile generates a contact sheet
+// for each file and where it's written.
+type thumbnailConfig struct {
+	enabled bool
+	dir     string
+	opts    thumbnail.Options
+}
+
+// processFile runs the lookup + probe pipeline for a single file and
+// reports its outcome as a fileResult, without touching shared state.
+func processFile(providers map[string]provider.MetadataProvider, nameRules *nameparser.Parser, metaCache *cache.Cache, cacheTTL time.Duration, hashAlgos map[string]bool, thumbs thumbnailConfig, job fileJob, fileListLength int) fileResult {
+	f := job.path
+	fileName := filepath.Base(f)
+
+	// Check if file exists.
+	fi, err := os.Stat(f)
 	if err != nil {
-		return "", "", err
+		return fileResult{index: job.index, err: fmt.Errorf("%s: No such file or directory.", f)}
 	}
-	defer resp.Body.Close()
 
-	utf8, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
-	if err != nil {
-		return "", "", fmt.Errorf("Encoding error: %v", err)
+	// Parse the provider token, season/episode, and resolution out of
+	// fileName using the configured rule chain. A file that doesn't match
+	// any rule (or matches one with no provider token) is skipped with a
+	// warning instead of aborting the whole run.
+	parsed, _, matched := nameRules.Parse(fileName)
+	if !matched || parsed.Provider == "" {
+		return fileResult{index: job.index, skip: true, progress: "\x1b[33;1mWARNING: skipping \"" + fileName + "\": no filename rule matched a known provider token.\x1b[0m\n"}
 	}
+	season, episode := parsed.Season, parsed.Episode
+	rW, rH := parsed.Width, parsed.Height
 
-	body, err := ioutil.ReadAll(utf8)
-	if err != nil {
-		return "", "", fmt.Errorf("IO error: %v", err)
+	providerName, providerID := parsed.Provider, parsed.ID
+	p, ok := providers[providerName]
+	if !ok {
+		return fileResult{index: job.index, err: fmt.Errorf("No metadata provider registered for %q.", providerName)}
+	}
+	// The sidecar provider reads a file next to the media rather than
+	// looking an ID up remotely, so it keys off the file path instead.
+	lookupID, cacheKey := providerID, providerName+":"+providerID
+	if providerName == "sidecar" {
+		lookupID, cacheKey = f, providerName+":"+f
 	}
 
-	m := movie{}
-	err = json.Unmarshal(body, &m)
-	if err != nil {
-		return "", "", fmt.Errorf("json.Unmarshal error: %v", err)
+	// Hash and probe the file concurrently, since neither depends on the
+	// other's result and both stream the whole file once.
+	var hashResult hash.Result
+	var hashErr error
+	var file *ffinfo.File
+	var probeErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		hashResult, hashErr = hash.Compute(f, hashAlgos)
+	}()
+	go func() {
+		defer wg.Done()
+		file, probeErr = ffinfo.Probe(f)
+	}()
+	wg.Wait()
+	if hashErr != nil {
+		return fileResult{index: job.index, err: hashErr}
+	}
+	if probeErr != nil {
+		return fileResult{index: job.index, err: errors.New("ffInfo: Could not get metadata from file")}
+	}
+
+	// A renamed file still has the same content hash, so use (ed2k,
+	// size) as a fallback cache key when the primary lookup misses.
+	fileHashKey := ""
+	if hashResult.Ed2k != "" {
+		fileHashKey = fmt.Sprintf("filehash:%s:%d", hashResult.Ed2k, fi.Size())
 	}
 
-	movieName := ""
-	if m.Title == "" {
-		movieName = m.OriginalTitle // Use original title is Russian title is missing.
-	} else {
-		movieName = m.Title
+	// Get movieName and movieType from the provider, or the cache.
+	movieName, movieType, err := lookupMovie(metaCache, p, cacheKey, fileHashKey, lookupID, cacheTTL)
+	if movieName == "" || movieType == "" {
+		if err != nil {
+			return fileResult{index: job.index, err: err}
+		}
+		return fileResult{index: job.index, err: fmt.Errorf("lookupMovie: Could not get data from %s", providerName)}
+	}
+	// Add season and episode numbers to movieName if movieType is SHOW.
+	if movieType == "SHOW" {
+		if season != "" || episode != "" {
+			movieName = movieName + ". " + season + " сезон. " + episode + " серия"
+		} else {
+			movieName = movieName + ". ####"
+		}
 	}
+
+	// Get file duration.
+	durationString := file.Format.Duration
+	duration, err := strconv.ParseFloat(durationString, 64)
 	if err != nil {
-		return "", "", fmt.Errorf("TRANSLIT error: %v", err)
+		return fileResult{index: job.index, err: err}
+	}
+	durationInMinutes := int(duration / 60)
+
+	// Get file duration type according to durationTypes list.
+	// 30 < x <= 60
+	// x = 60
+	durationInt := durationTypes[0]
+	for _, d := range durationTypes[1:] {
+		if durationInMinutes <= d {
+			durationInt = d
+		} else {
+			break
+		}
+	}
+	durationString = fmt.Sprintf("%02d", durationInt) + " минут"
+
+	// Determine if resolution is SD or HD.
+	resolution := "SD"
+	if rW > 1024 || rH > 576 {
+		resolution = "HD"
 	}
 
-	return movieName, m.Type, nil
+	// Summarize the video/audio/subtitle streams ffprobe found.
+	tech := techinfo.FromProbe(file)
+
+	// Generate a contact sheet next to the report, if requested. The
+	// sheet name is keyed off the file's content hash when available, or
+	// its input index otherwise, so two files sharing a basename (common
+	// for per-season/per-disc layouts) don't collide on the same sheet.
+	thumbnailPath := ""
+	if thumbs.enabled {
+		sheetKey := strconv.Itoa(job.index)
+		if hashResult.Ed2k != "" {
+			sheetKey = hashResult.Ed2k
+		}
+		base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		sheetName := fmt.Sprintf("%s-%s.jpg", base, sheetKey)
+		thumbnailPath = filepath.Join(thumbs.dir, sheetName)
+		if err := thumbnail.Generate(f, duration, thumbnailPath, thumbs.opts); err != nil {
+			return fileResult{index: job.index, err: err}
+		}
+	}
+
+	hashes := report.Hashes{}
+	if hashResult.CRC32 != "" {
+		hashes["crc32"] = hashResult.CRC32
+	}
+	if hashResult.SHA1 != "" {
+		hashes["sha1"] = hashResult.SHA1
+	}
+	if hashResult.Ed2k != "" {
+		hashes["ed2k"] = hashResult.Ed2k
+	}
+	if hashResult.Ed2kRed != "" {
+		hashes["ed2k_red"] = hashResult.Ed2kRed
+	}
+
+	record := report.Record{
+		MovieName:  movieName,
+		ProviderID: providerID,
+		Duration:   durationString + " " + resolution,
+		Timecode:   secondsToHHMMSS(duration),
+		Video:      tech.VideoSummary(),
+		Audio:      tech.AudioSummary(),
+		Subtitles:  tech.SubtitlesSummary(),
+		Tech:       tech,
+		Hashes:     hashes,
+		Thumbnail:  thumbnailPath,
+		FileName:   fileName,
+	}
+	progress := fmt.Sprintf("%"+strconv.Itoa(len(strconv.Itoa(fileListLength)))+"d", job.index+1) + "/" + strconv.Itoa(fileListLength) + "  " + truncPad(movieName, 32, 'l') + "  " + truncPad(providerID, 8, 'l') + "  " + truncPad(durationString+" "+resolution, 12, 'l') + "  " + secondsToHHMMSS(duration) + "  " + truncPad(fileName, 32, 'l') + "\n"
+
+	return fileResult{index: job.index, record: record, progress: progress}
+}
+
+// strongestHash picks the strongest hash available in h (preferring ed2k,
+// then sha1, then crc32) and returns it as "algo=value", so duplicate
+// detection still works when only some algorithms were requested.
+func strongestHash(h report.Hashes) string {
+	for _, algo := range []string{"ed2k", "sha1", "crc32"} {
+		if v, ok := h[algo]; ok {
+			return algo + "=" + v
+		}
+	}
+	return ""
 }
 
 // round rounds floats into integer numbers.
@@ -169,6 +369,31 @@ func secondsToHHMMSS(s float64) string {
 }
 
 func main() {
+	flag.Parse()
+
+	if *jobsFlag < 1 {
+		consolePrint("\x1b[31;1mERROR: --jobs must be at least 1.\x1b[0m\n")
+		os.Exit(1)
+	}
+
+	providers := provider.Default()
+
+	// Open the persistent metadata lookup cache unless disabled.
+	var metaCache *cache.Cache
+	if !*noCacheFlag {
+		if err := os.MkdirAll(*cacheDirFlag, 0775); err != nil {
+			consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+			os.Exit(1)
+		}
+		var err error
+		metaCache, err = cache.Open(filepath.Join(*cacheDirFlag, "kinopoisk.db"))
+		if err != nil {
+			consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+			os.Exit(1)
+		}
+		defer metaCache.Close()
+	}
+
 	// Read fileList and convert it into slice of strings.
 	fileList, err := readLines(fileListName)
 	if err != nil {
@@ -181,91 +406,137 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create empty output file.
-	outputFile, err := os.Create(outputFileName)
+	// Create the report in the requested format.
+	reporter, outputPath, err := report.New(*formatFlag, *outputFlag)
 	if err != nil {
 		consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+		os.Exit(1)
 	}
-	defer outputFile.Close()
+	consolePrint("Writing report to \"" + outputPath + "\".\n")
 
-	// For each file.
-	for i, f := range fileList {
-		// Get fileName from filePath.
-		fileName := filepath.Base(f)
+	hashAlgos := hash.ParseAlgorithms(*hashFlag)
 
-		// Check if file exists.
-		if _, err := os.Stat(f); err != nil {
-			consolePrint("\x1b[31;1m", f, ": No such file or directory.\x1b[0m\n")
-			return
+	// Load the filename parsing rule chain, falling back to the built-in
+	// rules when no config file is given.
+	nameRules := nameparser.Default()
+	if *filenameRulesFlag != "" {
+		nameRules, err = nameparser.Load(*filenameRulesFlag)
+		if err != nil {
+			consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+			os.Exit(1)
 		}
+	}
 
-		// Get KinoPoisk ID from fileName.
-		season := regexpMap["seCoid"].ReplaceAllString(fileName, "${1}")
-		episode := regexpMap["seCoid"].ReplaceAllString(fileName, "${2}")
-		coid := regexpMap["seCoid"].ReplaceAllString(fileName, "${3}")
-		rW, _ := strconv.Atoi(regexpMap["seCoid"].ReplaceAllString(fileName, "${4}"))
-		rH, _ := strconv.Atoi(regexpMap["seCoid"].ReplaceAllString(fileName, "${5}"))
-		if coid == fileName || coid == "" {
-			consolePrint("\x1b[31;1m", "FileName is wrong.", "\x1b[0m\n")
-			consolePrint("MUST BE: .*coid(\\d+).*\n\n")
-			return
+	// Set up contact sheet generation, if requested.
+	var thumbs thumbnailConfig
+	if *thumbnailsFlag {
+		cols, rows, err := thumbnail.ParseGrid(*thumbnailsGridFlag)
+		if err != nil {
+			consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+			os.Exit(1)
 		}
-
-		// Get movieName and movieType from KinoPisk API.
-		movieName, movieType, err := getMetaFromKP(coid)
-		if movieName == "" || movieType == "" {
-			if err != nil {
-				consolePrint("\x1b[31;1m", err, ".\x1b[0m\n")
-			}
-			consolePrint("\x1b[33;1m", "getMetaFromKP: Could not get data from KinoPoisk", "\x1b[0m\n")
-			return
+		thumbsDir := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "-thumbnails"
+		if err := os.MkdirAll(thumbsDir, 0775); err != nil {
+			consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+			os.Exit(1)
 		}
-		// Add season and episode numbers to movieName if movieType is SHOW.
-		if movieType == "SHOW" {
-			if season != "" || episode != "" {
-				movieName = movieName + ". " + season + " сезон. " + episode + " серия"
-			} else {
-				movieName = movieName + ". ####"
-			}
+		thumbs = thumbnailConfig{
+			enabled: true,
+			dir:     thumbsDir,
+			opts:    thumbnail.Options{Cols: cols, Rows: rows, Width: *thumbnailsWidthFlag, Quality: *thumbnailsQualityFlag},
 		}
+	}
 
-		// Get file duration.
-		file, err := ffinfo.Probe(f)
-		if err != nil {
-			consolePrint("\x1b[31;1m", "ffInfo: Could not get metadata from file", "\x1b[0m\n")
-			return
+	// The durations list must be sorted in decreasing order. Do this once
+	// up front since workers read durationTypes concurrently below.
+	sort.Sort(sort.Reverse(sort.IntSlice(durationTypes)))
+
+	jobs := make(chan fileJob)
+	results := make(chan fileResult)
+	progress := make(chan string)
+
+	// Workers fan out the per-file lookup + probe pipeline.
+	var workers sync.WaitGroup
+	for w := 0; w < *jobsFlag; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				results <- processFile(providers, nameRules, metaCache, *cacheTTLFlag, hashAlgos, thumbs, job, fileListLength)
+			}
+		}()
+	}
+	go func() {
+		for i, f := range fileList {
+			jobs <- fileJob{index: i, path: f}
 		}
-		durationString := file.Format.Duration
-		duration, err := strconv.ParseFloat(durationString, 64)
-		if err != nil {
-			consolePrint("\x1b[31;1m", err, ".\x1b[0m\n")
-			return
+		close(jobs)
+	}()
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Printer serializes progress output so concurrent workers can't
+	// interleave their ansi escape sequences.
+	var printerDone sync.WaitGroup
+	printerDone.Add(1)
+	go func() {
+		defer printerDone.Done()
+		for line := range progress {
+			consolePrint(line)
 		}
-		durationInMinutes := int(duration / 60)
-
-		// The durations list must be sorted in decreasing order.
-		sort.Sort(sort.Reverse(sort.IntSlice(durationTypes)))
-
-		// Get file duration type according to durationTypes list.
-		// 30 < x <= 60
-		// x = 60
-		durationInt := durationTypes[0]
-		for _, d := range durationTypes[1:] {
-			if durationInMinutes <= d {
-				durationInt = d
-			} else {
+	}()
+
+	// Collector writes results to the report in the original input order,
+	// buffering whatever arrives out of order until its turn comes up.
+	// seenHashes tracks the strongest hash seen per file so far, to warn
+	// on duplicates without aborting the run.
+	seenHashes := make(map[string]string)
+	pending := make(map[int]fileResult)
+	next := 0
+	for res := range results {
+		pending[res.index] = res
+		for {
+			r, ok := pending[next]
+			if !ok {
 				break
 			}
+			delete(pending, next)
+			if r.skip {
+				progress <- r.progress
+				next++
+				continue
+			}
+			if r.err != nil {
+				close(progress)
+				printerDone.Wait()
+				reporter.Close()
+				consolePrint("\x1b[31;1m", r.err, "\x1b[0m\n")
+				os.Exit(1)
+			}
+			if err := reporter.WriteRecord(r.record); err != nil {
+				close(progress)
+				printerDone.Wait()
+				reporter.Close()
+				consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+				os.Exit(1)
+			}
+			if key := strongestHash(r.record.Hashes); key != "" {
+				if prev, dup := seenHashes[key]; dup {
+					progress <- "\x1b[33;1mWARNING: \"" + r.record.FileName + "\" looks like a duplicate of \"" + prev + "\" (" + key + ").\x1b[0m\n"
+				}
+				seenHashes[key] = r.record.FileName
+			}
+			progress <- r.progress
+			next++
 		}
-		durationString = fmt.Sprintf("%02d", durationInt) + " минут"
-
-		// Determine if resolution is SD or HD.
-		resolution := "SD"
-		if rW > 1024 || rH > 576 {
-			resolution = "HD"
-		}
+	}
+	close(progress)
+	printerDone.Wait()
 
-		writeStringToFile(outputFile, movieName+"\t"+coid+"\t"+durationString+" "+resolution+"\t"+secondsToHHMMSS(duration)+"\t"+fileName+"\n", 0775)
-		consolePrint(fmt.Sprintf("%"+strconv.Itoa(len(strconv.Itoa(fileListLength)))+"d", i+1) + "/" + strconv.Itoa(fileListLength) + "  " + truncPad(movieName, 32, 'l') + "  " + truncPad(coid, 8, 'l') + "  " + truncPad(durationString+" "+resolution, 12, 'l') + "  " + secondsToHHMMSS(duration) + "  " + truncPad(fileName, 32, 'l') + "\n")
+	if err := reporter.Close(); err != nil {
+		consolePrint("\x1b[31;1m", err, "\x1b[0m\n")
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,171 @@
+// Package nameparser extracts a metadata provider token and descriptive
+// tags (season, episode, resolution, language, edition) out of a media
+// file name. Matching is driven by an ordered chain of regex rules —
+// the first rule whose pattern matches the file name wins — so new
+// naming conventions can be added via config instead of editing code.
+package nameparser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result is everything nameparser could extract from a file name.
+// Provider and ID are empty when the matching rule doesn't identify a
+// metadata provider (e.g. a bare "S01E02" convention with no ID token).
+type Result struct {
+	Provider string
+	ID       string
+	Season   string
+	Episode  string
+	Width    int
+	Height   int
+	Lang     string
+	Edition  string
+}
+
+// Rule is one named-capture-group regex tried against the file name.
+// Named groups "id", "provider", "season", "episode", "width", "height",
+// "lang", and "edition" are copied into the Result when present; any
+// other named groups are ignored. Provider, if set, is used as the
+// result's provider whenever the rule matches and the pattern itself
+// has no "provider" group — letting a rule just say which provider it's
+// for instead of having to capture a constant.
+type Rule struct {
+	Name     string `yaml:"name"`
+	Provider string `yaml:"provider,omitempty"`
+	Pattern  string `yaml:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// config is the on-disk shape of a rules file.
+type config struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Parser holds a compiled, ordered chain of rules.
+type Parser struct {
+	rules []Rule
+}
+
+// New compiles rules into a Parser. Rules are tried in order; the first
+// one to match a given file name wins.
+func New(rules []Rule) (*Parser, error) {
+	compiled := make([]Rule, len(rules))
+	for i, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("nameparser: rule %q: %s", r.Name, err)
+		}
+		r.re = re
+		compiled[i] = r
+	}
+	return &Parser{rules: compiled}, nil
+}
+
+// Load reads a YAML rules file and returns a Parser for it.
+func Load(path string) (*Parser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("nameparser: %s: %s", path, err)
+	}
+	return New(cfg.Rules)
+}
+
+// Default returns the built-in rule chain: the tool's original
+// coid/tmdb/imdb/sidecar token conventions, plus common third-party
+// conventions (plain S01E02, and Plex/Jellyfin "Title.Year.1080p").
+func Default() *Parser {
+	p, err := New(DefaultRules())
+	if err != nil {
+		// DefaultRules is a compile-time constant; a failure here is a
+		// programmer error, not a runtime condition callers can handle.
+		panic(err)
+	}
+	return p
+}
+
+// DefaultRules is the rule chain used by Default.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			// Season/episode can appear on either side of the coid token
+			// (e.g. "..._s01e02_coid12345_..." or "..._coid12345_s01e02_...").
+			Name:     "kinopoisk",
+			Provider: "kinopoisk",
+			Pattern:  `(?:s(?P<season>\d{2})e(?P<episode>\d{2,4}).*?coid(?P<id>\d+)|coid(?P<id>\d+).*?s(?P<season>\d{2})e(?P<episode>\d{2,4})|coid(?P<id>\d+)).*?_r(?P<width>\d+)x(?P<height>\d+)p`,
+		},
+		{
+			Name:     "tmdb",
+			Provider: "tmdb",
+			Pattern:  `tmdb(?P<id>\d+).*?(?:s(?P<season>\d{2})e(?P<episode>\d{2,4}))?`,
+		},
+		{
+			Name:     "imdb",
+			Provider: "imdb",
+			Pattern:  `imdb ?(?P<id>tt\d+).*?(?:s(?P<season>\d{2})e(?P<episode>\d{2,4}))?`,
+		},
+		{
+			Name:     "sidecar",
+			Provider: "sidecar",
+			Pattern:  `nfo:`,
+		},
+		{
+			// Plex/Jellyfin: "Title (2020) [1080p].mkv", "Title.2020.1080p.WEBRip.mkv".
+			Name:    "plex-jellyfin",
+			Pattern: `(?i)[.( \[](?P<height>480|576|720|1080|2160)p[.) \]]`,
+		},
+		{
+			// Bare "S01E02" with no ID token at all.
+			Name:    "standard-episode",
+			Pattern: `(?i)s(?P<season>\d{2})e(?P<episode>\d{2,4})`,
+		},
+	}
+}
+
+// Parse tries each rule in order against fileName and returns the
+// Result and matched rule's name from the first one that matches. ok is
+// false if no rule matched at all.
+func (p *Parser) Parse(fileName string) (result Result, ruleName string, ok bool) {
+	for _, r := range p.rules {
+		m := r.re.FindStringSubmatch(fileName)
+		if m == nil {
+			continue
+		}
+		result := Result{Provider: r.Provider}
+		for i, name := range r.re.SubexpNames() {
+			if i == 0 || name == "" || m[i] == "" {
+				continue
+			}
+			switch name {
+			case "id":
+				result.ID = m[i]
+			case "provider":
+				result.Provider = m[i]
+			case "season":
+				result.Season = m[i]
+			case "episode":
+				result.Episode = m[i]
+			case "width":
+				result.Width, _ = strconv.Atoi(m[i])
+			case "height":
+				result.Height, _ = strconv.Atoi(m[i])
+			case "lang":
+				result.Lang = m[i]
+			case "edition":
+				result.Edition = m[i]
+			}
+		}
+		return result, r.Name, true
+	}
+	return Result{}, "", false
+}
@@ -0,0 +1,75 @@
+package nameparser
+
+import "testing"
+
+// TestDefaultRulesKinopoisk pins the documented coid filename convention,
+// where season/episode can appear on either side of the coid token.
+func TestDefaultRulesKinopoisk(t *testing.T) {
+	p := Default()
+
+	cases := []struct {
+		name         string
+		fileName     string
+		wantRule     string
+		wantID       string
+		wantSeason   string
+		wantEpisode  string
+		wantW, wantH int
+	}{
+		{
+			name:        "season/episode before coid",
+			fileName:    "Show_Name_s01e02_coid12345_x264_r1920x1080p.mkv",
+			wantRule:    "kinopoisk",
+			wantID:      "12345",
+			wantSeason:  "01",
+			wantEpisode: "02",
+			wantW:       1920,
+			wantH:       1080,
+		},
+		{
+			name:        "season/episode after coid",
+			fileName:    "Show_Name_coid12345_s01e02_x264_r1920x1080p.mkv",
+			wantRule:    "kinopoisk",
+			wantID:      "12345",
+			wantSeason:  "01",
+			wantEpisode: "02",
+			wantW:       1920,
+			wantH:       1080,
+		},
+		{
+			name:     "movie with no season/episode",
+			fileName: "Movie_Name_coid12345_x264_r1920x1080p.mkv",
+			wantRule: "kinopoisk",
+			wantID:   "12345",
+			wantW:    1920,
+			wantH:    1080,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result, ruleName, ok := p.Parse(c.fileName)
+			if !ok {
+				t.Fatalf("Parse(%q): no rule matched", c.fileName)
+			}
+			if ruleName != c.wantRule {
+				t.Errorf("ruleName = %q, want %q", ruleName, c.wantRule)
+			}
+			if result.ID != c.wantID {
+				t.Errorf("ID = %q, want %q", result.ID, c.wantID)
+			}
+			if result.Season != c.wantSeason {
+				t.Errorf("Season = %q, want %q", result.Season, c.wantSeason)
+			}
+			if result.Episode != c.wantEpisode {
+				t.Errorf("Episode = %q, want %q", result.Episode, c.wantEpisode)
+			}
+			if result.Width != c.wantW {
+				t.Errorf("Width = %d, want %d", result.Width, c.wantW)
+			}
+			if result.Height != c.wantH {
+				t.Errorf("Height = %d, want %d", result.Height, c.wantH)
+			}
+		})
+	}
+}
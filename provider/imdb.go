@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// OMDbAPIURL and OMDbAPIKey for the OMDb API, used to resolve IMDb IDs
+// (there is no free official IMDb API).
+// Must be set here before compilation.
+var OMDbAPIURL = "https://www.omdbapi.com/"
+var OMDbAPIKey string
+
+// IMDb looks up movies and shows by their IMDb ID (the "imdb tt" token).
+type IMDb struct{}
+
+// Name returns the provider's short identifier.
+func (p *IMDb) Name() string { return "imdb" }
+
+type omdbResponse struct {
+	Title string `json:"Title"`
+	Type  string `json:"Type"`
+}
+
+// LookupByID takes an IMDb ID (e.g. "tt0133093") and returns the movie or
+// show's metadata.
+func (p *IMDb) LookupByID(ctx context.Context, id string) (*Movie, error) {
+	if OMDbAPIKey == "" {
+		return nil, errors.New("API key for OMDb api is not provided")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", OMDbAPIURL+"?i="+id+"&apikey="+OMDbAPIKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("IO error: %v", err)
+	}
+
+	r := omdbResponse{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error: %v", err)
+	}
+	if r.Title == "" {
+		return nil, errors.New("OMDb api returned no title")
+	}
+
+	movieType := "FILM"
+	if strings.EqualFold(r.Type, "series") {
+		movieType = "SHOW"
+	}
+
+	return &Movie{Title: r.Title, Type: movieType}, nil
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/net/html/charset"
+)
+
+// APIURL and ClientID for the KinoPoisk API.
+// Must be set here before compilation.
+var APIURL string
+var ClientID string
+
+// Kinopoisk looks up movies by their KinoPoisk ID (the "coid" token).
+type Kinopoisk struct{}
+
+// Name returns the provider's short identifier.
+func (p *Kinopoisk) Name() string { return "kinopoisk" }
+
+// LookupByID takes a KinoPoisk ID and returns the movie's metadata.
+func (p *Kinopoisk) LookupByID(ctx context.Context, id string) (*Movie, error) {
+	if ClientID == "" {
+		return nil, errors.New("clientID for KinoPoisk api is not provided")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", APIURL+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Clientid", ClientID)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	utf8, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("Encoding error: %v", err)
+	}
+
+	body, err := ioutil.ReadAll(utf8)
+	if err != nil {
+		return nil, fmt.Errorf("IO error: %v", err)
+	}
+
+	m := Movie{}
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error: %v", err)
+	}
+
+	if m.Title == "" {
+		m.Title = m.OriginalTitle // Use original title if Russian title is missing.
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,35 @@
+// Package provider defines the MetadataProvider interface used to resolve
+// a movie or show's name and type from a provider-specific ID (extracted
+// from the file name by the nameparser package), and the lookup registry
+// that main.go dispatches through.
+package provider
+
+import (
+	"context"
+)
+
+// Movie is the metadata returned by a provider lookup.
+type Movie struct {
+	Title         string `json:"title"`
+	OriginalTitle string `json:"originalTitle"`
+	Type          string `json:"type"`
+}
+
+// MetadataProvider resolves a provider-specific ID to movie metadata.
+type MetadataProvider interface {
+	// Name returns the provider's short identifier, e.g. "kinopoisk".
+	Name() string
+	// LookupByID resolves id to metadata.
+	LookupByID(ctx context.Context, id string) (*Movie, error)
+}
+
+// Default returns the standard set of providers, keyed by the provider
+// names nameparser.Rule.Provider can produce.
+func Default() map[string]MetadataProvider {
+	return map[string]MetadataProvider{
+		"kinopoisk": &Kinopoisk{},
+		"tmdb":      &TMDB{},
+		"imdb":      &IMDb{},
+		"sidecar":   &Sidecar{},
+	}
+}
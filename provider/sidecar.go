@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Sidecar reads metadata from a local .json or .nfo file next to the
+// media, for catalogs that already have it and shouldn't need a network
+// lookup. Unlike the other providers, LookupByID expects id to be the
+// media file's path rather than a remote ID.
+type Sidecar struct{}
+
+// Name returns the provider's short identifier.
+func (p *Sidecar) Name() string { return "sidecar" }
+
+// sidecarNFO is the subset of the common Kodi/Jellyfin NFO schema we read.
+type sidecarNFO struct {
+	XMLName       xml.Name `xml:"movie"`
+	Title         string   `xml:"title"`
+	OriginalTitle string   `xml:"originaltitle"`
+}
+
+// LookupByID reads metadata for the media file at path id from a
+// "<base>.json" sidecar, falling back to a "<base>.nfo" sidecar.
+func (p *Sidecar) LookupByID(ctx context.Context, id string) (*Movie, error) {
+	if id == "" {
+		return nil, errors.New("sidecar provider requires the media file path")
+	}
+	base := strings.TrimSuffix(id, filepath.Ext(id))
+
+	if data, err := ioutil.ReadFile(base + ".json"); err == nil {
+		m := Movie{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal error: %v", err)
+		}
+		if m.Title == "" {
+			m.Title = m.OriginalTitle
+		}
+		if m.Type == "" {
+			m.Type = "FILM"
+		}
+		return &m, nil
+	}
+
+	data, err := ioutil.ReadFile(base + ".nfo")
+	if err != nil {
+		return nil, fmt.Errorf("no .json or .nfo sidecar found next to %q", id)
+	}
+
+	nfo := sidecarNFO{}
+	if err := xml.Unmarshal(data, &nfo); err != nil {
+		return nil, fmt.Errorf("xml.Unmarshal error: %v", err)
+	}
+
+	title := nfo.Title
+	if title == "" {
+		title = nfo.OriginalTitle
+	}
+	if title == "" {
+		return nil, fmt.Errorf("%s.nfo has no <title>", base)
+	}
+
+	return &Movie{Title: title, OriginalTitle: nfo.OriginalTitle, Type: "FILM"}, nil
+}
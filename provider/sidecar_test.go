@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSidecarLookupByIDJSONDefaultsType covers a JSON sidecar that omits
+// the "type" field (plausible for a third-party shop's schema): it must
+// default to "FILM", not leave a present title with an empty type, since
+// main.go treats an empty movie type as a fatal error for the whole run.
+func TestSidecarLookupByIDJSONDefaultsType(t *testing.T) {
+	dir := t.TempDir()
+	mediaPath := filepath.Join(dir, "Movie_Name.mkv")
+	jsonPath := filepath.Join(dir, "Movie_Name.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"title":"Movie Name"}`), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	p := &Sidecar{}
+	m, err := p.LookupByID(context.Background(), mediaPath)
+	if err != nil {
+		t.Fatalf("LookupByID: %v", err)
+	}
+	if m.Type != "FILM" {
+		t.Errorf("Type = %q, want %q", m.Type, "FILM")
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// TMDBAPIURL and TMDBAPIKey for the TMDB API.
+// Must be set here before compilation.
+var TMDBAPIURL = "https://api.themoviedb.org/3/"
+var TMDBAPIKey string
+
+// TMDB looks up movies and shows by their TMDB ID (the "tmdb" token). It
+// tries the movie endpoint first and falls back to the tv endpoint, since
+// the ID alone doesn't say which kind it is.
+type TMDB struct{}
+
+// Name returns the provider's short identifier.
+func (p *TMDB) Name() string { return "tmdb" }
+
+type tmdbResponse struct {
+	Title         string `json:"title"`
+	OriginalTitle string `json:"original_title"`
+	Name          string `json:"name"`
+	OriginalName  string `json:"original_name"`
+}
+
+// LookupByID takes a TMDB ID and returns the movie or show's metadata.
+func (p *TMDB) LookupByID(ctx context.Context, id string) (*Movie, error) {
+	if TMDBAPIKey == "" {
+		return nil, errors.New("API key for TMDB api is not provided")
+	}
+
+	if m, err := p.fetch(ctx, "movie", id); err == nil {
+		m.Type = "FILM"
+		return m, nil
+	}
+
+	m, err := p.fetch(ctx, "tv", id)
+	if err != nil {
+		return nil, err
+	}
+	m.Type = "SHOW"
+	return m, nil
+}
+
+func (p *TMDB) fetch(ctx context.Context, kind, id string) (*Movie, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", TMDBAPIURL+kind+"/"+id+"?api_key="+TMDBAPIKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TMDB api returned status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("IO error: %v", err)
+	}
+
+	r := tmdbResponse{}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error: %v", err)
+	}
+
+	m := &Movie{Title: r.Title, OriginalTitle: r.OriginalTitle}
+	if kind == "tv" {
+		m.Title, m.OriginalTitle = r.Name, r.OriginalName
+	}
+	if m.Title == "" {
+		return nil, errors.New("TMDB api returned no title")
+	}
+	return m, nil
+}
@@ -0,0 +1,34 @@
+package report
+
+import (
+	"encoding/csv"
+	"os"
+)
+
+var csvHeader = []string{"movieName", "providerId", "duration", "timecode", "video", "audio", "subtitles", "hashes", "thumbnail", "fileName"}
+
+// csvReporter writes RFC 4180 quoted CSV, one record per row.
+type csvReporter struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func newCSVReporter(f *os.File) *csvReporter {
+	w := csv.NewWriter(f)
+	w.Write(csvHeader)
+	return &csvReporter{f: f, w: w}
+}
+
+func (r *csvReporter) WriteRecord(rec Record) error {
+	rec = rec.stripped()
+	return r.w.Write([]string{rec.MovieName, rec.ProviderID, rec.Duration, rec.Timecode, rec.Video, rec.Audio, rec.Subtitles, rec.Hashes.String(), rec.Thumbnail, rec.FileName})
+}
+
+func (r *csvReporter) Close() error {
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
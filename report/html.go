@@ -0,0 +1,96 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"os"
+)
+
+var htmlColumns = []string{"Movie", "ID", "Duration", "Timecode", "Video", "Audio", "Subtitles", "Hashes", "Thumbnail", "File"}
+
+// htmlReporter buffers every record and writes out a single self-
+// contained HTML page with a sortable table on Close.
+type htmlReporter struct {
+	f       *os.File
+	records []Record
+}
+
+func newHTMLReporter(f *os.File) *htmlReporter {
+	return &htmlReporter{f: f}
+}
+
+func (r *htmlReporter) WriteRecord(rec Record) error {
+	r.records = append(r.records, rec.stripped())
+	return nil
+}
+
+func (r *htmlReporter) Close() error {
+	if _, err := r.f.WriteString(htmlHeader); err != nil {
+		r.f.Close()
+		return err
+	}
+
+	for _, col := range htmlColumns {
+		fmt.Fprintf(r.f, "<th onclick=\"sortByColumn(this)\">%s</th>", html.EscapeString(col))
+	}
+	if _, err := r.f.WriteString("</tr></thead><tbody>\n"); err != nil {
+		r.f.Close()
+		return err
+	}
+
+	for _, rec := range r.records {
+		thumbnailCell := ""
+		if rec.Thumbnail != "" {
+			thumbnailCell = fmt.Sprintf(`<a href="%[1]s"><img src="%[1]s" height="90"></a>`, html.EscapeString(rec.Thumbnail))
+		}
+		fmt.Fprintf(r.f, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(rec.MovieName), html.EscapeString(rec.ProviderID), html.EscapeString(rec.Duration), html.EscapeString(rec.Timecode),
+			html.EscapeString(rec.Video), html.EscapeString(rec.Audio), html.EscapeString(rec.Subtitles), html.EscapeString(rec.Hashes.String()), thumbnailCell, html.EscapeString(rec.FileName))
+	}
+
+	if _, err := r.f.WriteString(htmlFooter); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>yarpt report</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { cursor: pointer; background: #eee; }
+</style>
+<script>
+function sortByColumn(th) {
+	var table = th.closest("table");
+	var tbody = table.querySelector("tbody");
+	var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+	var asc = th.getAttribute("data-asc") !== "true";
+	th.getAttribute("data-asc");
+	Array.prototype.forEach.call(th.parentNode.children, function(cell) { cell.removeAttribute("data-asc"); });
+	th.setAttribute("data-asc", asc);
+	var rows = Array.prototype.slice.call(tbody.querySelectorAll("tr"));
+	rows.sort(function(a, b) {
+		var x = a.children[index].textContent;
+		var y = b.children[index].textContent;
+		return asc ? x.localeCompare(y) : y.localeCompare(x);
+	});
+	rows.forEach(function(row) { tbody.appendChild(row); });
+}
+</script>
+</head>
+<body>
+<table>
+<thead><tr>`
+
+const htmlFooter = `</tbody>
+</table>
+</body>
+</html>
+`
@@ -0,0 +1,35 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// jsonReporter buffers every record and writes them out as a single JSON
+// array on Close.
+type jsonReporter struct {
+	f       *os.File
+	records []Record
+}
+
+func newJSONReporter(f *os.File) *jsonReporter {
+	return &jsonReporter{f: f}
+}
+
+func (r *jsonReporter) WriteRecord(rec Record) error {
+	r.records = append(r.records, rec.stripped())
+	return nil
+}
+
+func (r *jsonReporter) Close() error {
+	data, err := json.MarshalIndent(r.records, "", "  ")
+	if err != nil {
+		r.f.Close()
+		return err
+	}
+	if _, err := r.f.Write(data); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
@@ -0,0 +1,25 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// ndjsonReporter writes one JSON object per line, so downstream tools can
+// stream the report without parsing a tab-delimited line format.
+type ndjsonReporter struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+func newNDJSONReporter(f *os.File) *ndjsonReporter {
+	return &ndjsonReporter{f: f, enc: json.NewEncoder(f)}
+}
+
+func (r *ndjsonReporter) WriteRecord(rec Record) error {
+	return r.enc.Encode(rec.stripped())
+}
+
+func (r *ndjsonReporter) Close() error {
+	return r.f.Close()
+}
@@ -0,0 +1,129 @@
+// Package report writes processed file records to an output format
+// picked via --format, instead of main.go hand-assembling a single
+// tab-separated line per file.
+package report
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/malashin/yarpt/techinfo"
+)
+
+var escapePattern = regexp.MustCompile(`(\x1b\[\d+m|\x1b\[\d+;\d+m)`)
+
+// Record is everything the report prints about one processed file.
+type Record struct {
+	MovieName  string `json:"movieName"`
+	ProviderID string `json:"providerId"`
+	Duration   string `json:"duration"` // e.g. "60 минут HD"
+	Timecode   string `json:"timecode"` // HH:MM:SS
+	Video      string `json:"video,omitempty"`
+	Audio      string `json:"audio,omitempty"`
+	Subtitles  string `json:"subtitles,omitempty"`
+	// Tech is the structured per-stream breakdown that Video/Audio/
+	// Subtitles are flattened summaries of. json/ndjson serialize it so
+	// downstream tools don't have to re-parse the summary strings; the
+	// text-based formats (tsv/csv/html) stick to the flattened fields.
+	Tech      techinfo.Report `json:"tech,omitempty"`
+	Hashes    Hashes          `json:"hashes,omitempty"`
+	Thumbnail string          `json:"thumbnail,omitempty"` // path to the generated contact sheet, if any
+	FileName  string          `json:"fileName"`
+}
+
+// Hashes holds the content hashes computed for a file, keyed by
+// algorithm name ("crc32", "sha1", "ed2k", "ed2k_red").
+type Hashes map[string]string
+
+// String renders the hashes as "algo=value" pairs in a fixed order, for
+// the text-based report formats.
+func (h Hashes) String() string {
+	if len(h) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, algo := range []string{"crc32", "sha1", "ed2k", "ed2k_red"} {
+		if v, ok := h[algo]; ok {
+			parts = append(parts, algo+"="+v)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// stripped returns r with ansi escape sequences removed from every field,
+// so they never leak into a structured or text report.
+func (r Record) stripped() Record {
+	r.MovieName = escapePattern.ReplaceAllString(r.MovieName, "")
+	r.ProviderID = escapePattern.ReplaceAllString(r.ProviderID, "")
+	r.Duration = escapePattern.ReplaceAllString(r.Duration, "")
+	r.Timecode = escapePattern.ReplaceAllString(r.Timecode, "")
+	r.Video = escapePattern.ReplaceAllString(r.Video, "")
+	r.Audio = escapePattern.ReplaceAllString(r.Audio, "")
+	r.Subtitles = escapePattern.ReplaceAllString(r.Subtitles, "")
+	r.Thumbnail = escapePattern.ReplaceAllString(r.Thumbnail, "")
+	r.FileName = escapePattern.ReplaceAllString(r.FileName, "")
+	return r
+}
+
+// Reporter writes processed file records to a report in some format.
+// WriteRecord is called once per file in the original input order; Close
+// finalizes the report (writing a footer for formats that need one) and
+// closes the underlying file.
+type Reporter interface {
+	WriteRecord(r Record) error
+	Close() error
+}
+
+// defaultExt maps a --format value to the extension used when --output
+// isn't given.
+func defaultExt(format string) (string, error) {
+	switch format {
+	case "", "tsv":
+		return "txt", nil
+	case "csv":
+		return "csv", nil
+	case "ndjson":
+		return "ndjson", nil
+	case "json":
+		return "json", nil
+	case "html":
+		return "html", nil
+	default:
+		return "", fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// New creates a Reporter for format, writing to path. If path is empty,
+// it defaults to "report.<extension>" for the chosen format.
+func New(format, path string) (Reporter, string, error) {
+	ext, err := defaultExt(format)
+	if err != nil {
+		return nil, "", err
+	}
+	if path == "" {
+		path = "report." + ext
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case "", "tsv":
+		return newTSVReporter(f), path, nil
+	case "csv":
+		return newCSVReporter(f), path, nil
+	case "ndjson":
+		return newNDJSONReporter(f), path, nil
+	case "json":
+		return newJSONReporter(f), path, nil
+	case "html":
+		return newHTMLReporter(f), path, nil
+	default:
+		f.Close()
+		return nil, "", fmt.Errorf("unknown report format %q", format)
+	}
+}
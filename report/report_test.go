@@ -0,0 +1,107 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testRecord() Record {
+	return Record{
+		MovieName:  "Test Movie",
+		ProviderID: "12345",
+		Duration:   "60 минут HD",
+		Timecode:   "00:01:30",
+		Video:      "hevc 3840x2160",
+		Hashes:     Hashes{"crc32": "deadbeef"},
+		FileName:   "test.mkv",
+	}
+}
+
+// writeOne builds format's reporter against a temp file, writes one
+// record through it and returns the file's contents.
+func writeOne(t *testing.T, format string, rec Record) string {
+	t.Helper()
+	r, path, err := New(format, filepath.Join(t.TempDir(), "report"))
+	if err != nil {
+		t.Fatalf("New(%q): %v", format, err)
+	}
+	if err := r.WriteRecord(rec); err != nil {
+		t.Fatalf("WriteRecord: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(data)
+}
+
+func TestTSVReporterWritesTabSeparatedLine(t *testing.T) {
+	rec := testRecord()
+	got := writeOne(t, "tsv", rec)
+	want := "Test Movie\t12345\t60 минут HD\t00:01:30\thevc 3840x2160\t\t\tcrc32=deadbeef\t\ttest.mkv\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCSVReporterWritesHeaderAndQuotedRow(t *testing.T) {
+	got := writeOne(t, "csv", testRecord())
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2 (header + row):\n%s", len(lines), got)
+	}
+	if lines[0] != strings.Join(csvHeader, ",") {
+		t.Errorf("header = %q, want %q", lines[0], strings.Join(csvHeader, ","))
+	}
+	if !strings.Contains(lines[1], "Test Movie") || !strings.Contains(lines[1], "crc32=deadbeef") {
+		t.Errorf("row = %q missing expected fields", lines[1])
+	}
+}
+
+func TestJSONReporterWritesStructuredArray(t *testing.T) {
+	got := writeOne(t, "json", testRecord())
+	var records []Record
+	if err := json.Unmarshal([]byte(got), &records); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if records[0].MovieName != "Test Movie" || records[0].Hashes["crc32"] != "deadbeef" {
+		t.Errorf("got %+v", records[0])
+	}
+}
+
+func TestNDJSONReporterWritesOneObjectPerLine(t *testing.T) {
+	got := writeOne(t, "ndjson", testRecord())
+	var rec Record
+	if err := json.Unmarshal([]byte(strings.TrimSpace(got)), &rec); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if rec.MovieName != "Test Movie" {
+		t.Errorf("MovieName = %q, want %q", rec.MovieName, "Test Movie")
+	}
+}
+
+func TestHashesStringOrdersAndOmitsMissingAlgorithms(t *testing.T) {
+	h := Hashes{"ed2k": "bbb", "crc32": "aaa"}
+	if got, want := h.String(), "crc32=aaa,ed2k=bbb"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := (Hashes{}).String(); got != "" {
+		t.Errorf("empty Hashes.String() = %q, want empty", got)
+	}
+}
+
+func TestRecordStrippedRemovesAnsiEscapes(t *testing.T) {
+	rec := Record{MovieName: "\x1b[31;1mRed Movie\x1b[0m"}
+	if got := rec.stripped().MovieName; got != "Red Movie" {
+		t.Errorf("got %q, want %q", got, "Red Movie")
+	}
+}
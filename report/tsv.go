@@ -0,0 +1,24 @@
+package report
+
+import "os"
+
+// tsvReporter writes one tab-separated line per record, matching the
+// tool's original (and still default) output format.
+type tsvReporter struct {
+	f *os.File
+}
+
+func newTSVReporter(f *os.File) *tsvReporter {
+	return &tsvReporter{f: f}
+}
+
+func (r *tsvReporter) WriteRecord(rec Record) error {
+	rec = rec.stripped()
+	_, err := r.f.WriteString(rec.MovieName + "\t" + rec.ProviderID + "\t" + rec.Duration + "\t" + rec.Timecode + "\t" +
+		rec.Video + "\t" + rec.Audio + "\t" + rec.Subtitles + "\t" + rec.Hashes.String() + "\t" + rec.Thumbnail + "\t" + rec.FileName + "\n")
+	return err
+}
+
+func (r *tsvReporter) Close() error {
+	return r.f.Close()
+}
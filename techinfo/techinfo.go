@@ -0,0 +1,204 @@
+// Package techinfo extracts a compact technical summary (video/audio/
+// subtitle streams, HDR flags, channel layout) from ffinfo.Probe output,
+// so the report can show more than just overall duration.
+package techinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/malashin/ffinfo"
+)
+
+// VideoStream summarizes one video stream's technical characteristics.
+type VideoStream struct {
+	CodecName      string  `json:"codecName"`
+	Profile        string  `json:"profile,omitempty"`
+	PixFmt         string  `json:"pixFmt,omitempty"`
+	ColorPrimaries string  `json:"colorPrimaries,omitempty"`
+	ColorTransfer  string  `json:"colorTransfer,omitempty"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	FrameRate      float64 `json:"frameRate,omitempty"`
+	BitRate        int64   `json:"bitRate,omitempty"`
+	HDRFormat      string  `json:"hdrFormat,omitempty"` // "", "HDR10", "HLG" or "Dolby Vision"
+}
+
+// AudioStream summarizes one audio stream's technical characteristics.
+type AudioStream struct {
+	CodecName     string `json:"codecName"`
+	ChannelLayout string `json:"channelLayout,omitempty"`
+	Channels      int    `json:"channels,omitempty"`
+	SampleRate    string `json:"sampleRate,omitempty"`
+	BitRate       int64  `json:"bitRate,omitempty"`
+	Language      string `json:"language,omitempty"`
+}
+
+// SubtitleStream summarizes one embedded subtitle stream.
+type SubtitleStream struct {
+	CodecName string `json:"codecName"`
+	Language  string `json:"language,omitempty"`
+	Forced    bool   `json:"forced,omitempty"`
+}
+
+// Report is the technical summary extracted from an ffinfo.File.
+type Report struct {
+	Video     []VideoStream    `json:"video,omitempty"`
+	Audio     []AudioStream    `json:"audio,omitempty"`
+	Subtitles []SubtitleStream `json:"subtitles,omitempty"`
+}
+
+// FromProbe builds a Report from the output of ffinfo.Probe.
+func FromProbe(file *ffinfo.File) Report {
+	var r Report
+	for _, s := range file.Streams {
+		switch s.CodecType {
+		case "video":
+			r.Video = append(r.Video, VideoStream{
+				CodecName:      s.CodecName,
+				Profile:        s.Profile,
+				PixFmt:         s.PixFmt,
+				ColorPrimaries: s.ColorPrimaries,
+				ColorTransfer:  s.ColorTransfer,
+				Width:          s.Width,
+				Height:         s.Height,
+				FrameRate:      parseFrameRate(s.AvgFrameRate),
+				BitRate:        parseInt64(s.BitRate),
+				HDRFormat:      hdrFormat(s),
+			})
+		case "audio":
+			r.Audio = append(r.Audio, AudioStream{
+				CodecName:     s.CodecName,
+				ChannelLayout: channelLayout(s),
+				Channels:      s.Channels,
+				SampleRate:    s.SampleRate,
+				BitRate:       parseInt64(s.BitRate),
+				Language:      s.Tags.Language,
+			})
+		case "subtitle":
+			r.Subtitles = append(r.Subtitles, SubtitleStream{
+				CodecName: s.CodecName,
+				Language:  s.Tags.Language,
+				Forced:    s.Disposition.Forced != 0,
+			})
+		}
+	}
+	return r
+}
+
+// VideoSummary renders the video streams as a single compact field, e.g.
+// "hevc Main10 3840x2160 23.976fps HDR10".
+func (r Report) VideoSummary() string {
+	parts := make([]string, 0, len(r.Video))
+	for _, v := range r.Video {
+		s := v.CodecName
+		if v.Profile != "" {
+			s += " " + v.Profile
+		}
+		if v.Width > 0 && v.Height > 0 {
+			s += fmt.Sprintf(" %dx%d", v.Width, v.Height)
+		}
+		if v.FrameRate > 0 {
+			s += fmt.Sprintf(" %.3ffps", v.FrameRate)
+		}
+		if v.HDRFormat != "" {
+			s += " " + v.HDRFormat
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// AudioSummary renders the audio streams as a single compact field, e.g.
+// "eac3 5.1 rus; aac 2.0 eng".
+func (r Report) AudioSummary() string {
+	parts := make([]string, 0, len(r.Audio))
+	for _, a := range r.Audio {
+		s := a.CodecName
+		if a.ChannelLayout != "" {
+			s += " " + a.ChannelLayout
+		}
+		if a.Language != "" {
+			s += " " + a.Language
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SubtitlesSummary renders the subtitle streams as a single compact
+// field, e.g. "eng; rus (forced)".
+func (r Report) SubtitlesSummary() string {
+	parts := make([]string, 0, len(r.Subtitles))
+	for _, s := range r.Subtitles {
+		label := s.Language
+		if label == "" {
+			label = s.CodecName
+		}
+		if s.Forced {
+			label += " (forced)"
+		}
+		parts = append(parts, label)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// channelLayout falls back to a numeric 2.0/5.1/7.1-style layout derived
+// from Channels when ffprobe didn't report one directly.
+func channelLayout(s ffinfo.Stream) string {
+	if s.ChannelLayout != "" {
+		return s.ChannelLayout
+	}
+	switch s.Channels {
+	case 1:
+		return "1.0"
+	case 2:
+		return "2.0"
+	case 6:
+		return "5.1"
+	case 8:
+		return "7.1"
+	default:
+		if s.Channels > 0 {
+			return strconv.Itoa(s.Channels) + ".0"
+		}
+		return ""
+	}
+}
+
+// hdrFormat flags HDR10, HLG and Dolby Vision based on the stream's
+// color metadata and codec tag.
+func hdrFormat(s ffinfo.Stream) string {
+	tag := strings.ToLower(s.CodecTagString)
+	switch {
+	case strings.Contains(tag, "dvh1"), strings.Contains(tag, "dvhe"):
+		return "Dolby Vision"
+	case s.ColorTransfer == "arib-std-b67":
+		return "HLG"
+	case s.ColorTransfer == "smpte2084" && s.ColorPrimaries == "bt2020":
+		return "HDR10"
+	default:
+		return ""
+	}
+}
+
+func parseInt64(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// parseFrameRate parses an ffprobe "num/den" frame rate fraction into a
+// float, e.g. "24000/1001" -> 23.976.
+func parseFrameRate(s string) float64 {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return 0
+	}
+	num, err1 := strconv.ParseFloat(parts[0], 64)
+	den, err2 := strconv.ParseFloat(parts[1], 64)
+	if err1 != nil || err2 != nil || den == 0 {
+		return 0
+	}
+	return num / den
+}
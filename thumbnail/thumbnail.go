@@ -0,0 +1,140 @@
+// Package thumbnail generates a single contact-sheet image per file by
+// sampling evenly-spaced frames via ffmpeg and tiling them into a grid,
+// so a report can be eyeballed for wrong-aspect or black-frame deliveries
+// without opening every file.
+package thumbnail
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Options controls how a contact sheet is generated.
+type Options struct {
+	Cols    int // tiles per row
+	Rows    int // tiles per column
+	Width   int // width of each tile, in pixels
+	Quality int // JPEG quality, 1 (worst) to 100 (best)
+}
+
+// DefaultOptions mirror the --thumbnails-grid/-width/-quality flag defaults.
+var DefaultOptions = Options{Cols: 4, Rows: 4, Width: 320, Quality: 85}
+
+// ParseGrid parses a "COLSxROWS" flag value such as "4x4".
+func ParseGrid(s string) (cols, rows int, err error) {
+	parts := strings.SplitN(s, "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid grid %q, want COLSxROWS", s)
+	}
+	cols, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid grid %q: %s", s, err)
+	}
+	rows, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid grid %q: %s", s, err)
+	}
+	if cols < 1 || rows < 1 {
+		return 0, 0, fmt.Errorf("invalid grid %q: dimensions must be positive", s)
+	}
+	return cols, rows, nil
+}
+
+// qscale maps a 1-100 JPEG quality to ffmpeg's inverted 2-31 -qscale:v range.
+func qscale(quality int) int {
+	if quality < 1 {
+		quality = 1
+	}
+	if quality > 100 {
+		quality = 100
+	}
+	return 31 - (quality-1)*29/99
+}
+
+// Generate runs ffmpeg against src, sampling opts.Cols*opts.Rows frames
+// evenly spaced across duration (in seconds) and tiling them into a
+// single JPEG contact sheet written to dstPath.
+//
+// Each sample is extracted with its own fast (pre-input) -ss seek instead
+// of decoding the file from start to finish, so the cost stays
+// proportional to the grid size rather than to the file's length.
+func Generate(src string, duration float64, dstPath string, opts Options) error {
+	count := opts.Cols * opts.Rows
+	if count < 1 {
+		return fmt.Errorf("thumbnail: grid must have at least one tile")
+	}
+	if duration <= 0 {
+		return fmt.Errorf("thumbnail: unknown duration for %q", src)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "yarpt-thumbnail-*")
+	if err != nil {
+		return fmt.Errorf("thumbnail: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// One frame every duration/count seconds, centered in its slot,
+	// spreads the grid evenly across the whole file instead of
+	// clustering near the start.
+	interval := duration / float64(count)
+	frames := make([]string, count)
+	for i := 0; i < count; i++ {
+		ts := interval*float64(i) + interval/2
+		framePath := filepath.Join(tmpDir, fmt.Sprintf("frame-%03d.jpg", i))
+		cmd := exec.Command("ffmpeg", "-y",
+			"-ss", strconv.FormatFloat(ts, 'f', 3, 64), "-i", src,
+			"-vframes", "1", "-vf", fmt.Sprintf("scale=%d:-2", opts.Width),
+			framePath)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("ffmpeg: %s: %s", err, strings.TrimSpace(string(out)))
+		}
+		frames[i] = framePath
+	}
+
+	args := []string{"-y"}
+	for _, f := range frames {
+		args = append(args, "-i", f)
+	}
+	args = append(args,
+		"-filter_complex", fmt.Sprintf("xstack=inputs=%d:layout=%s", count, xstackLayout(opts.Cols, opts.Rows)),
+		"-frames:v", "1", "-qscale:v", strconv.Itoa(qscale(opts.Quality)), dstPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// xstackLayout builds the position list for ffmpeg's xstack filter that
+// tiles cols*rows same-sized inputs into a grid, e.g. for 2x2:
+// "0_0|w0_0|0_h0|w0_h0".
+func xstackLayout(cols, rows int) string {
+	var cells []string
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			cells = append(cells, stackOffset(c, "w0")+"_"+stackOffset(r, "h0"))
+		}
+	}
+	return strings.Join(cells, "|")
+}
+
+// stackOffset renders an xstack axis offset as a multiple of unit (the
+// first input's width or height), since every extracted frame is scaled
+// to the same dimensions.
+func stackOffset(n int, unit string) string {
+	switch n {
+	case 0:
+		return "0"
+	case 1:
+		return unit
+	default:
+		return fmt.Sprintf("%d*%s", n, unit)
+	}
+}